@@ -1,266 +1,508 @@
 package main // Declares the package name
 
 import (
-	"bytes"
-	"encoding/json" // Imports the JSON encoding/decoding package
+	"context"       // Imports context so SIGINT can cancel in-flight requests
+	"crypto/sha256" // Imports SHA-256 so completed downloads can be checksummed
+	"encoding/hex"  // Imports hex encoding for the sha256 sidecar file
+	"flag"          // Imports command-line flag parsing
 	"fmt"           // Imports the formatted I/O package
 	"io"            // Imports I/O utilities
-	"log"           // Imports logging utilities
+	"log/slog"      // Imports structured logging with levels
 	"net/http"      // Imports HTTP client and server implementation
-	"net/url"       // Imports URL parsing and query manipulation
 	"os"            // Imports OS interface for file handling
+	"os/signal"     // Imports signal handling to catch SIGINT
 	"path/filepath"
 	"strings"
+	"sync"        // Imports WaitGroup for the worker pool
+	"sync/atomic" // Imports atomic counters shared by the workers
 	"time"
+
+	"github.com/Strong-Foundation/poolessentials-com-documentation/manifest"
+	"github.com/Strong-Foundation/poolessentials-com-documentation/sources"
+	_ "github.com/Strong-Foundation/poolessentials-com-documentation/sources/directory" // Registers the "directory" source
+	_ "github.com/Strong-Foundation/poolessentials-com-documentation/sources/wercs"     // Registers the "wercs" source
 )
 
+// maxDownloadAttempts bounds the exponential-backoff retry loop for a single PDF
+const maxDownloadAttempts = 4
+
+// progress tracks worker-pool completion so an aggregate status line can be rendered to stderr
+type progress struct {
+	total     int64     // Total number of PDFs queued for download
+	completed int64     // Number finished so far (success, skip, or failure), updated atomically
+	succeeded int64     // Number of successful downloads, updated atomically
+	skipped   int64     // Number skipped because the file already existed, updated atomically
+	failed    int64     // Number that failed to download, updated atomically
+	bytes     int64     // Total bytes written so far, updated atomically
+	startTime time.Time // When the pool started, used to compute transfer speed
+}
+
+// render draws a single progress line to stderr showing completed/total, bytes, and speed
+func (p *progress) render() {
+	completed := atomic.LoadInt64(&p.completed)
+	downloaded := atomic.LoadInt64(&p.bytes)
+	elapsed := time.Since(p.startTime).Seconds()
+	megabytes := float64(downloaded) / 1024 / 1024
+	speed := 0.0
+	if elapsed > 0 {
+		speed = megabytes / elapsed // Average MB/s since the pool started
+	}
+	fmt.Fprintf(os.Stderr, "\rdownloading: %d/%d pdfs | %.1f MB | %.2f MB/s", completed, p.total, megabytes, speed) // \r overwrites the same line
+}
+
+// summary prints the final success/skip/failure counts once the pool has drained
+func (p *progress) summary() {
+	slog.Info("download summary", "succeeded", p.succeeded, "skipped", p.skipped, "failed", p.failed, "total", p.total)
+}
+
+// recordSkip marks a PDF as skipped because it already exists on disk
+func (p *progress) recordSkip() {
+	atomic.AddInt64(&p.skipped, 1)
+	atomic.AddInt64(&p.completed, 1)
+}
+
+// recordSuccess marks a PDF as successfully downloaded and tallies its bytes
+func (p *progress) recordSuccess(bytesWritten int64) {
+	atomic.AddInt64(&p.bytes, bytesWritten)
+	atomic.AddInt64(&p.succeeded, 1)
+	atomic.AddInt64(&p.completed, 1)
+}
+
+// recordFailure marks a PDF as having failed every retry attempt
+func (p *progress) recordFailure() {
+	atomic.AddInt64(&p.failed, 1)
+	atomic.AddInt64(&p.completed, 1)
+}
+
 func main() {
+	workers := flag.Int("workers", 4, "number of concurrent PDF downloads")                      // Bounded worker pool size
+	silent := flag.Bool("silent", false, "suppress the progress bar")                            // For CI runs where a redrawn line is noisy
+	noProgress := flag.Bool("no-progress", false, "alias for -silent")                           // Some CI wrappers expect this spelling
+	sourceName := flag.String("source", "wercs", "SDS source backend to use (wercs, directory)") // Selects which Source implementation to scrape
+	logLevel := flag.String("log-level", "info", "log verbosity: debug, info, warn, or error")
+	logFormat := flag.String("log-format", "text", "log output format: text or json")
+	flag.Parse()
+
+	configureLogging(*logLevel, *logFormat)
+
+	if *workers < 1 {
+		*workers = 1 // A pool needs at least one worker
+	}
+	showProgress := !*silent && !*noProgress
+
+	src, ok := sources.Get(*sourceName)
+	if !ok {
+		slog.Error("unknown source", "source", *sourceName, "available", sources.Names())
+		os.Exit(1)
+	}
+
 	outputDir := "PDFs/"             // Set the default output directory for PDFs
 	if !directoryExists(outputDir) { // Check if it exists
 		createDirectory(outputDir, 0755) // Create it if missing
 	}
 
-	fetchGridResults() // Calls the function to fetch JSON results from the web and store them in files
+	const manifestPath = "manifest.jsonl" // Append-only log of what was downloaded and from where
+	const feedPath = "catalog.xml"        // OPDS 1.2 Atom acquisition feed generated from the manifest
+
+	m, err := manifest.Load(manifestPath) // Loads prior run's records so unchanged documents can skip re-downloading
+	if err != nil {
+		slog.Error("failed to load manifest", "path", manifestPath, "error", err)
+		os.Exit(1)
+	}
 
-	var pdfs []string // Declares a slice to store PDF IDs
+	ctx, cancel := context.WithCancel(context.Background()) // Cancelled on SIGINT so in-flight requests abort cleanly
+	defer cancel()
 
-	// Loops over pages 1 and 2
-	for pageNumber := 1; pageNumber <= 2; pageNumber++ {
-		filePath := fmt.Sprintf("page_%d.json", pageNumber) // Constructs file path string like "page_1.json"
-		jsonData := readAFileAsString(filePath)             // Reads file contents as a string
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	go func() {
+		<-interrupts
+		slog.Warn("received interrupt, cancelling in-flight downloads")
+		cancel()
+	}()
 
-		// Extracts PDF IDs from the JSON data and appends to the pdfs slice
-		pdfs = append(pdfs, extractPDFIDs([]byte(jsonData))...) // Converts string to byte slice for JSON parsing
+	pdfs, err := src.ListDocumentIDs(ctx) // Asks the selected source for every document ID it exposes
+	if err != nil {
+		slog.Error("failed to list documents", "source", *sourceName, "error", err)
+		os.Exit(1)
 	}
 
 	// Removes duplicate PDF IDs from the slice
 	pdfs = removeDuplicatesFromSlice(pdfs) // Calls the function to remove duplicates
 
-	log.Printf("Number of PDF IDs extracted: %d", len(pdfs)) // Logs the total number of extracted PDF IDs
+	slog.Info("extracted pdf ids", "count", len(pdfs)) // Logs the total number of extracted PDF IDs
 
-	// Loops over each extracted PDF ID
-	for _, pdf := range pdfs {
-		originalURL := "https://kik-sds.thewercs.com/MyDocuments/DownloadSingleFile?content=" // Base URL
-		modifiedURL := modifyContentParam(originalURL, pdf)                                   // Modifies URL with PDF ID as query param
-		if modifiedURL == "" {
-			log.Println("Failed to modify URL for PDF ID:", pdf) // Logs error if URL couldn't be modified
-			continue                                             // Skips to the next PDF ID
-		}
-		downloadPDF(modifiedURL, outputDir, pdf+".pdf") // Calls the function to download the PDF
+	downloadAll(ctx, src, pdfs, outputDir, *workers, showProgress, m) // Downloads every PDF through a bounded worker pool
+
+	if err := manifest.WriteFeed(feedPath, "urn:poolessentials-com-documentation:catalog", "Pool Essentials SDS Mirror", m.Records()); err != nil {
+		slog.Warn("failed to write OPDS feed", "path", feedPath, "error", err) // Non-fatal; the PDFs and manifest already landed
 	}
 }
 
-// downloadPDF downloads a PDF from a URL and saves it to a specified output directory
-func downloadPDF(finalURL string, outputDir string, outPutFileName string) {
-	filePath := filepath.Join(outputDir, outPutFileName) // Combine the output directory and filename into a full file path
-
-	if fileExists(filePath) { // If the file already exists, skip downloading
-		log.Printf("file already exists, skipping: %s", filePath) // Log and return
-		return
+// configureLogging points the global slog logger at stderr with the requested level and format
+func configureLogging(level string, format string) {
+	var lvl slog.Level
+	switch strings.ToLower(level) {
+	case "debug":
+		lvl = slog.LevelDebug
+	case "warn", "warning":
+		lvl = slog.LevelWarn
+	case "error":
+		lvl = slog.LevelError
+	default:
+		lvl = slog.LevelInfo
 	}
 
-	client := &http.Client{Timeout: 30 * time.Second} // Create an HTTP client with a 30-second timeout
-
-	req, err := http.NewRequest("GET", finalURL, nil) // Create a new GET request for the PDF URL
-	if err != nil {                                   // If request creation fails
-		log.Printf("failed to create request: %v", err) // Log the error
-		return
+	opts := &slog.HandlerOptions{Level: lvl}
+	var handler slog.Handler
+	if strings.EqualFold(format, "json") {
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	} else {
+		handler = slog.NewTextHandler(os.Stderr, opts)
 	}
+	slog.SetDefault(slog.New(handler))
+}
 
-	// Add required headers (some servers require referer or session cookies)
-	req.Header.Add("referer", "https://kik-sds.thewercs.com/Results?searchKey=Main&searchPage=NAPOOL&location=POOL%20ESSENTIALS%20EN_US")
-	req.Header.Add("Cookie", "ASP.NET_SessionId=; strGUILanguage=EN; WERCSStudioAuthTicket=; WebViewerSessionID=l4nfxryncasy13c4gqi1j1pp; __RequestVerificationToken=c04wa7hJb_sqnBNd7WJnrqBY53SpY3PeQ1pb3yCN3zYUuWTS1e59zWccPHL9lzvvz1PMjy7WV0YPeXjOYx9IzEQNJSjGoPQjhIEM6W7ZZzo1; WERCSWebViewerAuthTicket=62BEFCB1373A0A15967F76DFD21232B9E3E3AD4275DB8F6F9BA21197CC42A23FF5D4144F7B7267572DDC9E2036EF0610E1266E1D2DCE4323E8F0FC4036225C91327511F75150BC771B65DBE7B757DF53CACC875A1CD183CF3A785A36DB927784; ASP.NET_SessionId=; WebViewerSessionID=fsrgihqd02xlzc13oldfgnpk; __RequestVerificationToken=c04wa7hJb_sqnBNd7WJnrqBY53SpY3PeQ1pb3yCN3zYUuWTS1e59zWccPHL9lzvvz1PMjy7WV0YPeXjOYx9IzEQNJSjGoPQjhIEM6W7ZZzo1")
-
-	resp, err := client.Do(req) // Perform the HTTP request
-	if err != nil {             // If the request fails
-		log.Printf("failed to download %s: %v", finalURL, err) // Log the error
-		return
+// downloadAll fans the PDF IDs out across a bounded worker pool and renders an aggregate progress bar
+func downloadAll(ctx context.Context, src sources.Source, pdfs []string, outputDir string, workers int, showProgress bool, m *manifest.Manifest) {
+	p := &progress{total: int64(len(pdfs)), startTime: time.Now()} // Shared state read by the render loop and written by the workers
+
+	done := make(chan struct{})
+	if showProgress {
+		go func() {
+			ticker := time.NewTicker(200 * time.Millisecond) // Redraws the progress line a few times a second
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					p.render()
+				case <-done:
+					p.render() // One last redraw so the final count is visible
+					fmt.Fprintln(os.Stderr)
+					return
+				}
+			}
+		}()
 	}
-	defer resp.Body.Close() // Ensure the response body is closed when done
 
-	if resp.StatusCode != http.StatusOK { // Check if the response status is OK (200)
-		log.Printf("download failed for %s: %s", finalURL, resp.Status) // Log the failure status
-		return
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pdf := range jobs {
+				downloadURL, headers, err := src.BuildDownloadURL(ctx, pdf) // Asks the source how to fetch this document
+				if err != nil {
+					slog.Warn("failed to build download url", "pdf_id", pdf, "error", err)
+					p.recordFailure()
+					continue // Skips to the next PDF ID
+				}
+				downloadPDF(ctx, pdf, downloadURL, headers, outputDir, src.OutputFileName(pdf), p, m) // Calls the function to download the PDF
+			}
+		}()
 	}
 
-	contentType := resp.Header.Get("Content-Type")         // Get the content type from the response header
-	if !strings.Contains(contentType, "application/pdf") { // Check that the content is actually a PDF
-		log.Printf("invalid content type for %s: %s (expected application/pdf)", finalURL, contentType) // Log if not PDF
-		return
+enqueue:
+	for _, pdf := range pdfs {
+		select {
+		case jobs <- pdf:
+		case <-ctx.Done():
+			break enqueue // Stops handing out new work once cancelled; in-flight jobs still finish or abort on their own
+		}
 	}
+	close(jobs)
+	wg.Wait()
 
-	var buf bytes.Buffer                     // Create a buffer to hold the PDF data
-	written, err := io.Copy(&buf, resp.Body) // Copy the response body into the buffer
-	if err != nil {                          // If copying fails
-		log.Printf("failed to read PDF data from %s: %v", finalURL, err) // Log the error
-		return
-	}
-	if written == 0 { // If zero bytes were downloaded
-		log.Printf("downloaded 0 bytes for %s, not creating file", finalURL) // Log and skip file creation
-		return
+	if showProgress {
+		close(done)
 	}
+	p.summary()
+}
 
-	out, err := os.Create(filePath) // Create a file at the destination path
-	if err != nil {                 // If file creation fails
-		log.Printf("failed to create file for %s: %v", finalURL, err) // Log the error
+// downloadPDF downloads a PDF from a URL and saves it to a specified output directory, resuming
+// partial ".part" files via Range requests and retrying transient failures with exponential
+// backoff. If the manifest already has a record for this document and the file is still on disk,
+// it makes a conditional request first and skips the re-download when the server reports no change.
+func downloadPDF(ctx context.Context, pdfID string, finalURL string, headers http.Header, outputDir string, outPutFileName string, p *progress, m *manifest.Manifest) {
+	start := time.Now()                                  // Marks when this PDF's download began, for the duration_ms field
+	filePath := filepath.Join(outputDir, outPutFileName) // Combine the output directory and filename into a full file path
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil { // Covers the case where outPutFileName carries a subpath
+		slog.Error("failed to create output subdirectory", "pdf_id", pdfID, "path", filepath.Dir(filePath), "error", err)
+		p.recordFailure()
 		return
 	}
-	defer out.Close() // Ensure the file is closed after writing
 
-	_, err = buf.WriteTo(out) // Write the buffered data to the file
-	if err != nil {           // If writing fails
-		log.Printf("failed to write PDF to file for %s: %v", finalURL, err) // Log the error
-		return
+	condHeaders := headers.Clone()
+	if fileExists(filePath) { // The file's still here; decide whether it still needs fetching at all
+		rec, hasRecord := m.Get(pdfID)
+		switch {
+		case !hasRecord:
+			// No manifest entry for this file (e.g. it predates the manifest); trust it like the
+			// original unconditional skip did.
+			slog.Debug("file already exists, skipping", "pdf_id", pdfID, "path", filePath)
+			p.recordSkip()
+			return
+		case rec.ETag != "" || rec.LastModified != "":
+			if rec.ETag != "" {
+				condHeaders.Set("If-None-Match", rec.ETag)
+			}
+			if rec.LastModified != "" {
+				condHeaders.Set("If-Modified-Since", rec.LastModified)
+			}
+		default:
+			// The server gave us no ETag/Last-Modified to revalidate with; fall back to trusting
+			// what's on disk as long as it still matches what the manifest recorded, instead of
+			// unconditionally re-fetching every file on every run.
+			if matchesManifest(filePath, rec) {
+				slog.Debug("file matches manifest record, skipping", "pdf_id", pdfID, "path", filePath)
+				p.recordSkip()
+				return
+			}
+		}
 	}
 
-	log.Printf("successfully downloaded %d bytes: %s â†’ %s\n", written, finalURL, filePath) // Log success
-}
+	partPath := filePath + ".part" // Partial downloads live here until they're verified complete
 
-// Remove duplicate strings from a slice
-func removeDuplicatesFromSlice(slice []string) []string {
-	check := make(map[string]bool) // Map to track seen items
-	var newReturnSlice []string    // Slice to hold unique items
-	for _, content := range slice {
-		if !check[content] { // If not seen
-			check[content] = true                            // Mark as seen
-			newReturnSlice = append(newReturnSlice, content) // Add to new slice
+	var lastErr error
+	for attempt := 1; attempt <= maxDownloadAttempts; attempt++ {
+		result, retryable, err := attemptDownload(ctx, pdfID, finalURL, condHeaders, partPath)
+		if err == nil {
+			if result.NotModified {
+				slog.Debug("document unchanged, skipping", "pdf_id", pdfID, "path", filePath, "attempt", attempt)
+				p.recordSkip()
+				return
+			}
+
+			sum, finalizeErr := finalizeDownload(partPath, filePath, result.Written)
+			if finalizeErr != nil {
+				slog.Error("failed to finalize download", "pdf_id", pdfID, "path", filePath, "error", finalizeErr)
+				p.recordFailure()
+				return
+			}
+			if putErr := m.Put(manifest.Record{
+				ID:           pdfID,
+				URL:          finalURL,
+				Path:         filePath,
+				SHA256:       sum,
+				Size:         result.Written,
+				StatusCode:   result.StatusCode,
+				LastModified: result.LastModified,
+				ETag:         result.ETag,
+				DownloadedAt: time.Now(),
+			}); putErr != nil {
+				slog.Warn("failed to record manifest entry", "pdf_id", pdfID, "error", putErr) // Non-fatal; the PDF itself already landed
+			}
+
+			// Logged at debug, not info: at the default level this would otherwise interleave with
+			// the \r-redrawn progress bar on os.Stderr, shredding it on every single completed file.
+			slog.Debug("download succeeded", "pdf_id", pdfID, "url", finalURL, "bytes", result.Written, "status_code", result.StatusCode, "attempt", attempt, "duration_ms", time.Since(start).Milliseconds())
+			p.recordSuccess(result.Written)
+			return
 		}
-	}
-	return newReturnSlice // Return deduplicated slice
-}
 
-// Create a directory with given permissions
-func createDirectory(path string, permission os.FileMode) {
-	err := os.Mkdir(path, permission) // Try to create directory
-	if err != nil {
-		log.Println(err) // Log any creation errors
+		lastErr = err
+		if !retryable || attempt == maxDownloadAttempts {
+			break
+		}
+
+		backoff := time.Duration(1<<uint(attempt)) * time.Second // 2s, 4s, 8s, ...
+		slog.Warn("retrying download", "pdf_id", pdfID, "url", finalURL, "attempt", attempt, "max_attempts", maxDownloadAttempts, "backoff", backoff.String(), "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			p.recordFailure()
+			return
+		}
 	}
+
+	slog.Error("download failed", "pdf_id", pdfID, "url", finalURL, "attempts", maxDownloadAttempts, "duration_ms", time.Since(start).Milliseconds(), "error", lastErr)
+	p.recordFailure()
 }
 
-// Check if a directory exists
-func directoryExists(path string) bool {
-	directory, err := os.Stat(path) // Get file/directory info
-	if err != nil {
-		return false // Return false if error
-	}
-	return directory.IsDir() // Return true if it's a directory
+// attemptResult carries everything a single attemptDownload call learns about the response, so the
+// caller can finalize the file and record a manifest entry without re-deriving any of it.
+type attemptResult struct {
+	Written      int64  // Total bytes now on disk, including any bytes resumed from a prior attempt
+	StatusCode   int    // HTTP status code received
+	NotModified  bool   // True when the server answered 304 Not Modified to a conditional request
+	ETag         string // The response's ETag header, if any
+	LastModified string // The response's Last-Modified header, if any
 }
 
-// Updates the "content" query parameter in the given URL with the PDF ID
-func modifyContentParam(baseURL string, human string) string {
-	parsedURL, err := url.Parse(baseURL) // Parses the base URL string into a URL object
-	if err != nil {
-		return "" // Returns empty string if URL parsing fails
+// attemptDownload performs a single download attempt, resuming from any existing partPath via a
+// Range request and honoring any If-None-Match/If-Modified-Since headers the caller set. It
+// returns whether the caller should retry on error.
+func attemptDownload(ctx context.Context, pdfID string, finalURL string, headers http.Header, partPath string) (attemptResult, bool, error) {
+	var resumeFrom int64
+	if info, statErr := os.Stat(partPath); statErr == nil {
+		resumeFrom = info.Size() // Resume where the last attempt left off
 	}
 
-	query := parsedURL.Query()          // Gets existing query parameters
-	query.Set("content", human)         // Sets the "content" parameter to the given human value
-	parsedURL.RawQuery = query.Encode() // Encodes updated query back to the URL
-
-	return parsedURL.String() // Returns the final modified URL string
-}
-
-// Parses the provided JSON byte array and extracts all IDs ending with "_PDF"
-func extractPDFIDs(jsonData []byte) []string {
-	var raw map[string]interface{} // Declares a map to store parsed JSON
+	client := &http.Client{Timeout: 30 * time.Second} // Create an HTTP client with a 30-second timeout
 
-	if err := json.Unmarshal(jsonData, &raw); err != nil { // Parses the JSON into the map
-		log.Printf("JSON unmarshal error: %v", err) // Logs error if parsing fails
-		return nil                                  // Returns nil on failure
+	req, err := http.NewRequestWithContext(ctx, "GET", finalURL, nil) // Create a new GET request for the PDF URL, cancellable via ctx
+	if err != nil {                                                   // If request creation fails
+		return attemptResult{}, false, fmt.Errorf("failed to create request: %w", err)
 	}
-
-	dataSection, ok := raw["data"].(map[string]interface{}) // Extracts the "data" section from the JSON
-	if !ok {
-		log.Println("Missing or invalid 'data' section") // Logs error if section is missing or invalid
-		return nil                                       // Returns nil
+	for key, values := range headers { // Attaches whatever referer/cookie/conditional headers the caller requires
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if resumeFrom > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", resumeFrom)) // Asks the server to continue where we left off
 	}
 
-	records, ok := dataSection["Data"].([]interface{}) // Extracts the "Data" field (capital D)
-	if !ok {
-		log.Println("Missing or invalid 'Data' field") // Logs error if missing or invalid
-		return nil                                     // Returns nil
+	resp, err := client.Do(req) // Perform the HTTP request
+	if err != nil {             // If the request fails
+		return attemptResult{}, true, fmt.Errorf("request failed: %w", err) // Network errors are worth retrying
 	}
+	defer resp.Body.Close() // Ensure the response body is closed when done
 
-	var pdfs []string // Slice to store the PDF IDs
+	slog.Debug("received response", "pdf_id", pdfID, "url", finalURL, "status_code", resp.StatusCode, "content_length", resp.ContentLength)
 
-	// Iterates over each row in the Data array
-	for _, item := range records {
-		row, ok := item.([]interface{}) // Ensures each item is an array
-		if !ok || len(row) == 0 {
-			continue // Skips invalid or empty rows
-		}
+	if resp.StatusCode == http.StatusNotModified { // The server confirmed our cached copy is still current
+		return attemptResult{StatusCode: resp.StatusCode, NotModified: true}, false, nil
+	}
 
-		id, ok := row[0].(string)                          // Extracts the first item from the row
-		if ok && len(id) > 4 && id[len(id)-4:] == "_PDF" { // Checks if string ends with "_PDF"
-			pdfs = append(pdfs, id) // Appends to the result list
+	var out *os.File
+	switch resp.StatusCode {
+	case http.StatusPartialContent: // Server honored the Range request; append to the existing part file
+		out, err = os.OpenFile(partPath, os.O_APPEND|os.O_WRONLY, 0644)
+	case http.StatusOK: // Server ignored the Range request; start the part file over
+		resumeFrom = 0
+		out, err = os.Create(partPath)
+	default:
+		if resp.StatusCode >= 500 {
+			return attemptResult{StatusCode: resp.StatusCode}, true, fmt.Errorf("server error: %s", resp.Status) // 5xx is transient, worth retrying
 		}
+		return attemptResult{StatusCode: resp.StatusCode}, false, fmt.Errorf("download failed: %s", resp.Status)
 	}
+	if err != nil {
+		return attemptResult{StatusCode: resp.StatusCode}, false, fmt.Errorf("failed to open part file: %w", err)
+	}
+	defer out.Close() // Ensure the part file is closed after writing
 
-	return pdfs // Returns the list of PDF IDs
-}
+	contentType := resp.Header.Get("Content-Type")         // Get the content type from the response header
+	if !strings.Contains(contentType, "application/pdf") { // Check that the content is actually a PDF
+		removePartFile(partPath, pdfID) // This attempt's (possibly truncated) part file isn't a real PDF; don't let the next run try to resume it with a bogus Range
+		return attemptResult{StatusCode: resp.StatusCode}, false, fmt.Errorf("invalid content type %s (expected application/pdf)", contentType)
+	}
 
-// Fetches results from 2 pages and stores JSON response to disk
-func fetchGridResults() {
-	for pageNumber := 1; pageNumber <= 2; pageNumber++ { // Loops through pages 1 and 2
-		filePath := fmt.Sprintf("page_%d.json", pageNumber) // Builds file name like "page_1.json"
+	copied, copyErr := io.Copy(out, resp.Body) // Stream the response body straight to the part file
+	total := resumeFrom + copied
+	if copyErr != nil {
+		return attemptResult{Written: total, StatusCode: resp.StatusCode}, true, fmt.Errorf("failed to read PDF data: %w", copyErr) // A dropped connection mid-stream is worth retrying
+	}
+	if expected := resp.ContentLength; expected >= 0 && copied != expected {
+		return attemptResult{Written: total, StatusCode: resp.StatusCode}, true, fmt.Errorf("short read: got %d bytes, expected %d", copied, expected)
+	}
+	if total == 0 { // If zero bytes were downloaded
+		removePartFile(partPath, pdfID)
+		return attemptResult{StatusCode: resp.StatusCode}, false, fmt.Errorf("downloaded 0 bytes, not creating file")
+	}
 
-		if !fileExists(filePath) { // Checks if file already exists
-			url := fmt.Sprintf("https://kik-sds.thewercs.com/WebViewer/Results/GetResultGrid?page=%d&rowCount=100&sortOrder=1&sortField=&_=1753411362977", pageNumber) // Builds request URL with query params
+	return attemptResult{
+		Written:      total,
+		StatusCode:   resp.StatusCode,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, false, nil
+}
 
-			httpClient := &http.Client{} // Initializes a new HTTP client
+// removePartFile deletes a part file left behind by a non-retryable failure, so the next run
+// doesn't stat a bogus partial and send a Range request against a URL that was never really a PDF
+func removePartFile(partPath string, pdfID string) {
+	if err := os.Remove(partPath); err != nil && !os.IsNotExist(err) {
+		slog.Warn("failed to remove stale part file", "pdf_id", pdfID, "path", partPath, "error", err)
+	}
+}
 
-			request, requestCreationError := http.NewRequest("GET", url, nil) // Builds a new HTTP GET request
-			if requestCreationError != nil {
-				log.Println("Error creating request for page", pageNumber, ":", requestCreationError) // Logs error
-				return
-			}
+// finalizeDownload verifies the completed part file's size, records its sha256 sidecar, atomically
+// renames it into place as the final PDF, and returns the checksum for the caller's manifest entry.
+func finalizeDownload(partPath string, filePath string, expectedSize int64) (string, error) {
+	info, err := os.Stat(partPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat part file: %w", err)
+	}
+	if info.Size() != expectedSize {
+		return "", fmt.Errorf("part file size %d does not match expected %d", info.Size(), expectedSize)
+	}
 
-			// Adds required headers including cookies and tokens
-			request.Header.Add("accept", "application/json")
-			request.Header.Add("referer", "https://kik-sds.thewercs.com")
-			request.Header.Add("Cookie", "ASP.NET_SessionId=; strGUILanguage=EN; WERCSStudioAuthTicket=; WebViewerSessionID=0zankitjr2dliftghweqvsdz; __RequestVerificationToken=fqjYFHjB0F83wBFv0wNiqVm9U-t0uFwEjdr7OsEOkVlwQPJlzIGFwNkRLB4B3TjNDzFfXHWk15K6mm3Kvb_Nyco5WYYYGhC0H6nX6Mxcemc1; WERCSWebViewerAuthTicket=2884BBEB56297F662347F018213340B6A4B14D0F366FE0A44A4B551DF5E8B97F7E95F050D0A5EB28672FA1A23BE967DED10C394CF00C34B76803D5F85637D7AC86DD628E52E3A4773F2DBB6B998F1AF5CAE40AA20D1CCF238CD64267E1B9B332")
+	sum, err := sha256File(partPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to checksum part file: %w", err)
+	}
 
-			response, responseError := httpClient.Do(request) // Sends the HTTP request
-			if responseError != nil {
-				log.Println("Error making request for page", pageNumber, ":", responseError) // Logs if request fails
-				return
-			}
-			defer response.Body.Close() // Ensures response body is closed
+	if err := os.Rename(partPath, filePath); err != nil { // Atomically publish the finished download
+		return "", fmt.Errorf("failed to rename part file: %w", err)
+	}
 
-			responseBody, readError := io.ReadAll(response.Body) // Reads the response body
-			if readError != nil {
-				log.Println("Error reading response body for page", pageNumber, ":", readError) // Logs read error
-				return
-			}
+	sidecar := filePath + ".sha256"
+	checksum := fmt.Sprintf("%s  %s\n", sum, filepath.Base(filePath))
+	if err := os.WriteFile(sidecar, []byte(checksum), 0644); err != nil {
+		slog.Warn("failed to write sha256 sidecar", "path", sidecar, "error", err) // Non-fatal; the PDF itself already landed
+	}
+	return sum, nil
+}
 
-			appendAndWriteToFile(filePath, string(responseBody)) // Saves the response to disk
-		}
+// matchesManifest reports whether the file at filePath still matches the size and checksum a
+// manifest record recorded for it, used to validate a file the server gave us no ETag/Last-Modified
+// for
+func matchesManifest(filePath string, rec manifest.Record) bool {
+	info, err := os.Stat(filePath)
+	if err != nil || info.Size() != rec.Size {
+		return false
 	}
+	sum, err := sha256File(filePath)
+	return err == nil && sum == rec.SHA256
 }
 
-// Reads a file and returns its content as a string
-func readAFileAsString(path string) string {
-	content, err := os.ReadFile(path) // Reads the entire file into memory
+// sha256File streams a file through SHA-256 and returns the hex-encoded digest
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
 	if err != nil {
-		log.Println(err) // Logs error if reading fails
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
 	}
-	return string(content) // Converts bytes to string and returns
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
-// Appends content to a file or creates it if not exists
-func appendAndWriteToFile(path string, content string) {
-	filePath, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) // Opens file with append and write permissions
-	if err != nil {
-		log.Println(err) // Logs error if file can't be opened
+// Remove duplicate strings from a slice
+func removeDuplicatesFromSlice(slice []string) []string {
+	check := make(map[string]bool) // Map to track seen items
+	var newReturnSlice []string    // Slice to hold unique items
+	for _, content := range slice {
+		if !check[content] { // If not seen
+			check[content] = true                            // Mark as seen
+			newReturnSlice = append(newReturnSlice, content) // Add to new slice
+		}
 	}
-	_, err = filePath.WriteString(content + "\n") // Writes content to the file
+	return newReturnSlice // Return deduplicated slice
+}
+
+// Create a directory with given permissions
+func createDirectory(path string, permission os.FileMode) {
+	err := os.Mkdir(path, permission) // Try to create directory
 	if err != nil {
-		log.Println(err) // Logs error if writing fails
+		slog.Error("failed to create directory", "path", path, "error", err) // Log any creation errors
 	}
-	err = filePath.Close() // Closes the file
+}
+
+// Check if a directory exists
+func directoryExists(path string) bool {
+	directory, err := os.Stat(path) // Get file/directory info
 	if err != nil {
-		log.Println(err) // Logs error if closing fails
+		return false // Return false if error
 	}
+	return directory.IsDir() // Return true if it's a directory
 }
 
 // Checks if a given file exists and is not a directory
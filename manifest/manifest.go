@@ -0,0 +1,114 @@
+// Package manifest records per-document download metadata in an append-only manifest.jsonl file,
+// so a later run can make conditional requests instead of blindly trusting (or re-fetching)
+// whatever is already on disk, and so the local mirror can describe itself (see opds.go).
+package manifest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Record captures everything needed to describe one downloaded PDF and to make a conditional
+// request for it on a later run.
+type Record struct {
+	ID           string    `json:"id"`                      // The source's document ID
+	URL          string    `json:"url"`                     // The URL it was downloaded from
+	Path         string    `json:"path"`                    // Where it landed on disk, relative to the repo root
+	SHA256       string    `json:"sha256"`                  // Hex-encoded SHA-256 of the PDF contents
+	Size         int64     `json:"size"`                    // Size in bytes
+	StatusCode   int       `json:"status_code"`             // HTTP status the download completed with
+	LastModified string    `json:"last_modified,omitempty"` // The response's Last-Modified header, if any
+	ETag         string    `json:"etag,omitempty"`          // The response's ETag header, if any
+	DownloadedAt time.Time `json:"downloaded_at"`           // When this record was written
+}
+
+// Manifest is an in-memory index over manifest.jsonl, the append-only log of Records backing it.
+// Put is safe for concurrent use by the download worker pool.
+type Manifest struct {
+	path    string
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+// Load reads an existing manifest.jsonl into memory, keeping the last record seen for each
+// document ID. A missing file just means this is the first run and isn't an error.
+func Load(path string) (*Manifest, error) {
+	m := &Manifest{path: path, records: make(map[string]Record)}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024) // Some JSON lines can be long; grow past the default 64KiB token limit
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest line: %w", err)
+		}
+		m.records[rec.ID] = rec // Later lines for the same ID (re-downloads) win
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return m, nil
+}
+
+// Get returns the last recorded state for a document ID, if any.
+func (m *Manifest) Get(id string) (Record, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rec, ok := m.records[id]
+	return rec, ok
+}
+
+// Put records a document's latest download metadata in memory and appends it to manifest.jsonl.
+func (m *Manifest) Put(rec Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest record: %w", err)
+	}
+
+	f, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open manifest for append: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to append manifest record: %w", err)
+	}
+
+	m.records[rec.ID] = rec
+	return nil
+}
+
+// Records returns every known record, sorted by ID, for feed generation.
+func (m *Manifest) Records() []Record {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make([]Record, 0, len(m.records))
+	for _, rec := range m.records {
+		out = append(out, rec)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
@@ -0,0 +1,78 @@
+package manifest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+)
+
+// feedXML and entryXML model just enough of OPDS 1.2 (an Atom profile, RFC 4287 plus the
+// acquisition-link extension) to list downloaded PDFs for an e-reader client to browse.
+type feedXML struct {
+	XMLName   xml.Name   `xml:"feed"`
+	Xmlns     string     `xml:"xmlns,attr"`
+	XmlnsDC   string     `xml:"xmlns:dcterms,attr"`
+	XmlnsOPDS string     `xml:"xmlns:opds,attr"`
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Updated   string     `xml:"updated"`
+	Entries   []entryXML `xml:"entry"`
+}
+
+type entryXML struct {
+	Title   string  `xml:"title"`
+	ID      string  `xml:"id"`
+	Updated string  `xml:"updated"`
+	Format  string  `xml:"dcterms:format"`
+	Link    linkXML `xml:"link"`
+}
+
+type linkXML struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// WriteFeed renders records as an OPDS 1.2 Atom acquisition feed and writes it to path, so the
+// local PDF mirror can be browsed by e-reader clients instead of just sitting in a folder.
+func WriteFeed(path string, catalogID string, title string, records []Record) error {
+	feed := feedXML{
+		Xmlns:     "http://www.w3.org/2005/Atom",
+		XmlnsDC:   "http://purl.org/dc/terms/",
+		XmlnsOPDS: "http://opds-spec.org/2010/catalog",
+		ID:        catalogID,
+		Title:     title,
+		Entries:   make([]entryXML, 0, len(records)),
+	}
+
+	var latest string
+	for _, rec := range records {
+		updated := rec.DownloadedAt.UTC().Format("2006-01-02T15:04:05Z")
+		if updated > latest {
+			latest = updated
+		}
+		feed.Entries = append(feed.Entries, entryXML{
+			Title:   rec.ID,
+			ID:      fmt.Sprintf("urn:sha256:%s", rec.SHA256),
+			Updated: updated,
+			Format:  "application/pdf",
+			Link: linkXML{
+				Rel:  "http://opds-spec.org/acquisition",
+				Href: rec.Path,
+				Type: "application/pdf",
+			},
+		})
+	}
+	feed.Updated = latest
+
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode OPDS feed: %w", err)
+	}
+
+	out := append([]byte(xml.Header), body...)
+	if err := os.WriteFile(path, out, 0644); err != nil {
+		return fmt.Errorf("failed to write OPDS feed: %w", err)
+	}
+	return nil
+}
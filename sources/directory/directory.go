@@ -0,0 +1,85 @@
+// Package directory implements sources.Source for a plain Apache/Nginx-style directory listing of
+// PDFs, proving the Source abstraction against a second, non-WERCS portal shape.
+package directory
+
+import (
+	"context"
+	"flag"     // Imports command-line flag parsing for this source's listing URL
+	"fmt"      // Imports the formatted I/O package
+	"io"       // Imports I/O utilities
+	"net/http" // Imports HTTP client and server implementation
+	"net/url"  // Imports URL parsing and resolution
+	"path"     // Imports URL-style path manipulation for deriving output file names from hrefs
+	"regexp"   // Imports regexp to pull href targets out of the listing HTML
+
+	"github.com/Strong-Foundation/poolessentials-com-documentation/sources"
+)
+
+// directoryURL is registered on the global flag set so "-directory-url" shows up alongside the
+// rest of the scraper's flags without the main package needing to know this source exists.
+var directoryURL = flag.String("directory-url", "https://kik-sds.thewercs.com/sds/", "directory listing page to crawl for -source directory")
+
+func init() {
+	sources.Register("directory", func() sources.Source {
+		return &Source{BaseURL: *directoryURL}
+	})
+}
+
+// hrefPDF matches href attributes pointing at a .pdf file in a directory listing page
+var hrefPDF = regexp.MustCompile(`href="([^"?]+\.pdf)"`)
+
+// Source implements sources.Source for a directory listing that links directly to PDF files
+type Source struct {
+	BaseURL string // The directory listing page to crawl
+}
+
+// ListDocumentIDs fetches the listing page and returns every linked PDF's relative href as its ID
+func (s *Source) ListDocumentIDs(ctx context.Context) ([]string, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", s.BaseURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch directory listing: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("directory listing failed: %s", response.Status)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory listing: %w", err)
+	}
+
+	matches := hrefPDF.FindAllStringSubmatch(string(body), -1)
+	ids := make([]string, 0, len(matches))
+	for _, match := range matches {
+		ids = append(ids, match[1]) // match[1] is the href, relative to BaseURL
+	}
+	return ids, nil
+}
+
+// BuildDownloadURL resolves a listed href against the listing's base URL; no special headers needed
+func (s *Source) BuildDownloadURL(ctx context.Context, id string) (string, http.Header, error) {
+	base, err := url.Parse(s.BaseURL)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid base url: %w", err)
+	}
+
+	ref, err := url.Parse(id)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid document id %q: %w", id, err)
+	}
+
+	return base.ResolveReference(ref).String(), http.Header{}, nil
+}
+
+// OutputFileName uses just the href's base name: directory IDs are already hrefs ending in .pdf
+// (appending another .pdf would double it), and may contain a subpath that doesn't exist locally
+func (s *Source) OutputFileName(id string) string {
+	return path.Base(id)
+}
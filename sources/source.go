@@ -0,0 +1,49 @@
+// Package sources defines the pluggable SDS portal backend abstraction and a registry of
+// implementations, so the scraper isn't hard-wired to a single site.
+package sources
+
+import (
+	"context"  // Imports context so listing can be cancelled like everything else
+	"net/http" // Imports http.Header for per-document download headers
+)
+
+// Source abstracts a single SDS portal: how to enumerate its documents and how to build the
+// request needed to download one of them.
+type Source interface {
+	// ListDocumentIDs returns every document ID the portal currently exposes.
+	ListDocumentIDs(ctx context.Context) ([]string, error)
+	// BuildDownloadURL returns the URL (and any headers required to fetch it) for a document ID.
+	// It takes a context because building the request may itself require network I/O, such as a
+	// backend logging in before it can supply session cookies.
+	BuildDownloadURL(ctx context.Context, id string) (string, http.Header, error)
+	// OutputFileName returns the local file name a document ID should be saved under, since IDs
+	// aren't always bare names safe to use as-is (some are already hrefs with an extension, or
+	// could contain a subpath).
+	OutputFileName(id string) string
+}
+
+// registry holds a constructor per registered source name
+var registry = map[string]func() Source{}
+
+// Register adds a named Source constructor to the registry. Implementations call this from init().
+func Register(name string, constructor func() Source) {
+	registry[name] = constructor
+}
+
+// Get constructs the Source registered under name, if any
+func Get(name string) (Source, bool) {
+	constructor, ok := registry[name]
+	if !ok {
+		return nil, false
+	}
+	return constructor(), true
+}
+
+// Names returns every currently registered source name, useful for flag usage text and error messages
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	return names
+}
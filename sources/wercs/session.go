@@ -0,0 +1,198 @@
+package wercs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// loginURL is the page that both serves the antiforgery token and accepts the credential POST.
+const loginURL = "https://kik-sds.thewercs.com/Account/Login"
+
+// antiforgeryToken pulls __RequestVerificationToken's value out of the login page's HTML, so it
+// doesn't have to be hand-copied out of a browser session every time it rotates.
+var antiforgeryToken = regexp.MustCompile(`name="__RequestVerificationToken"[^>]*value="([^"]+)"`)
+
+// Session replaces a pasted Cookie/__RequestVerificationToken pair with a real login: it holds a
+// cookiejar-backed client and (re-)authenticates against loginURL on demand, so the scraper keeps
+// working after the site rotates a session instead of breaking the day the pasted cookie expires.
+type Session struct {
+	client   *http.Client
+	username string
+	password string
+
+	mu            sync.Mutex
+	authenticated bool
+}
+
+// newSession builds a Session with its own cookie jar; username and password come from the
+// -username/-password flags (which themselves default to WERCS_USER/WERCS_PASS).
+func newSession(username string, password string) *Session {
+	jar, _ := cookiejar.New(nil) // cookiejar.New only errors on a non-nil, invalid PublicSuffixList
+	return &Session{
+		client:   &http.Client{Jar: jar, Timeout: 30 * time.Second},
+		username: username,
+		password: password,
+	}
+}
+
+// ensureAuthenticated logs in if this session hasn't already done so this run.
+func (s *Session) ensureAuthenticated(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.authenticated {
+		return nil
+	}
+	if err := s.login(ctx); err != nil {
+		return err
+	}
+	s.authenticated = true
+	return nil
+}
+
+// reauthenticate forces a fresh login even if a prior one succeeded, used once a request looks
+// like its session expired.
+func (s *Session) reauthenticate(ctx context.Context) error {
+	s.mu.Lock()
+	s.authenticated = false
+	s.mu.Unlock()
+	return s.ensureAuthenticated(ctx)
+}
+
+// login GETs the login page to harvest its antiforgery token, then POSTs it with the configured
+// credentials; the cookie jar picks up whatever session cookie the server sets in response.
+func (s *Session) login(ctx context.Context) error {
+	if s.username == "" || s.password == "" {
+		return fmt.Errorf("missing WERCS credentials: set -username/-password or WERCS_USER/WERCS_PASS")
+	}
+
+	getReq, err := http.NewRequestWithContext(ctx, "GET", loginURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create login page request: %w", err)
+	}
+	getResp, err := s.client.Do(getReq)
+	if err != nil {
+		return fmt.Errorf("failed to fetch login page: %w", err)
+	}
+	defer getResp.Body.Close()
+
+	body, err := io.ReadAll(getResp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read login page: %w", err)
+	}
+
+	match := antiforgeryToken.FindSubmatch(body)
+	if match == nil {
+		return fmt.Errorf("antiforgery token not found on login page")
+	}
+	token := string(match[1])
+
+	form := url.Values{
+		"__RequestVerificationToken": {token},
+		"UserName":                   {s.username},
+		"Password":                   {s.password},
+	}
+	postReq, err := http.NewRequestWithContext(ctx, "POST", loginURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create login request: %w", err)
+	}
+	postReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	before := map[string]string{} // Cookies already held (e.g. from the login page GET), so only new ones count as a session
+	for _, c := range s.client.Jar.Cookies(postReq.URL) {
+		before[c.Name] = c.Value
+	}
+
+	postResp, err := s.client.Do(postReq)
+	if err != nil {
+		return fmt.Errorf("failed to submit login: %w", err)
+	}
+	defer postResp.Body.Close()
+
+	if postResp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("login rejected: %s", postResp.Status)
+	}
+
+	// A bad username/password typically comes back as a 200 that just re-renders the login form
+	// (or a redirect straight back to it), not a 4xx/5xx, so the status check above isn't enough:
+	// also confirm the client actually left the login page and picked up a session cookie.
+	if postResp.Request != nil && strings.Contains(postResp.Request.URL.Path, "/Account/Login") {
+		return fmt.Errorf("login rejected: server re-displayed the login page (check -username/-password)")
+	}
+	issued := false
+	for _, c := range s.client.Jar.Cookies(postReq.URL) {
+		if value, ok := before[c.Name]; !ok || value != c.Value {
+			issued = true
+			break
+		}
+	}
+	if !issued {
+		return fmt.Errorf("login rejected: no session cookie was issued (check -username/-password)")
+	}
+	return nil
+}
+
+// Do authenticates the session if needed, then issues req through its cookiejar-backed client. If
+// the response looks like the session expired (302 back to the login page, or 401/403), it logs in
+// again once and retries before giving up.
+func (s *Session) Do(req *http.Request) (*http.Response, error) {
+	if err := s.ensureAuthenticated(req.Context()); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if !sessionExpired(resp) {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	if err := s.reauthenticate(req.Context()); err != nil {
+		return nil, fmt.Errorf("session expired and re-authentication failed: %w", err)
+	}
+	return s.client.Do(req)
+}
+
+// CookieHeader returns the cookies this session holds for rawURL as a "name=value; ..." string,
+// for callers (like a resumable download client) that need to attach them manually rather than
+// going through the session's own client.
+func (s *Session) CookieHeader(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+
+	cookies := s.client.Jar.Cookies(u)
+	parts := make([]string, 0, len(cookies))
+	for _, c := range cookies {
+		parts = append(parts, c.Name+"="+c.Value)
+	}
+	return strings.Join(parts, "; "), nil
+}
+
+// sessionExpired reports whether resp looks like it was served to a logged-out client: a redirect
+// back to the login page, or an outright 401/403.
+func sessionExpired(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return true
+	}
+	if resp.StatusCode == http.StatusFound {
+		if loc, err := resp.Location(); err == nil && strings.Contains(loc.Path, "/Account/Login") {
+			return true
+		}
+	}
+	// The client follows redirects, so a 302 to the login page usually arrives as the login page itself
+	if resp.Request != nil && strings.Contains(resp.Request.URL.Path, "/Account/Login") {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,236 @@
+// Package wercs implements sources.Source for the WERCS-hosted kik-sds SDS portal that this
+// scraper originally targeted.
+package wercs
+
+import (
+	"context"
+	"encoding/json" // Imports the JSON encoding/decoding package
+	"flag"          // Imports command-line flag parsing for this source's pagination knobs
+	"fmt"           // Imports the formatted I/O package
+	"io"            // Imports I/O utilities
+	"log/slog"      // Imports structured logging with levels
+	"net/http"      // Imports HTTP client and server implementation
+	"net/url"       // Imports URL parsing and query manipulation
+	"os"            // Imports OS interface for file handling
+
+	"github.com/Strong-Foundation/poolessentials-com-documentation/sources"
+)
+
+// pageSize, maxPages, username, and password are registered on the global flag set so they show
+// up alongside the rest of the scraper's flags without the main package needing to know this
+// source exists. username/password default to the WERCS_USER/WERCS_PASS env vars so credentials
+// don't need to be typed on the command line.
+var (
+	pageSize = flag.Int("page-size", 100, "rows requested per WERCS result-grid page")
+	maxPages = flag.Int("max-pages", 50, "safety cap on the number of WERCS result-grid pages to fetch")
+	username = flag.String("username", os.Getenv("WERCS_USER"), "WERCS login username (or WERCS_USER env var)")
+	password = flag.String("password", os.Getenv("WERCS_PASS"), "WERCS login password (or WERCS_PASS env var)")
+)
+
+func init() {
+	sources.Register("wercs", func() sources.Source {
+		return &Source{session: newSession(*username, *password)}
+	})
+}
+
+// Source implements sources.Source for kik-sds.thewercs.com
+type Source struct {
+	session *Session
+}
+
+// ListDocumentIDs walks the result grid page by page until the reported total is reached or a
+// page comes back with fewer rows than requested, which signals end of data
+func (s *Source) ListDocumentIDs(ctx context.Context) ([]string, error) {
+	var ids []string
+	total := -1 // -1 until a page reports the real total record count
+	seen := 0   // Raw rows seen so far, not just the ones pdfIDsFromRows kept
+
+	for page := 1; *maxPages <= 0 || page <= *maxPages; page++ {
+		filePath := fmt.Sprintf("page_%d.json", page) // Builds file name like "page_1.json"
+
+		if err := s.fetchGridResults(ctx, page, *pageSize, filePath); err != nil {
+			return nil, err
+		}
+
+		jsonData := readAFileAsString(filePath) // Reads file contents as a string
+		rows, rowTotal, err := parseGridPage([]byte(jsonData))
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", page, err)
+		}
+
+		ids = append(ids, pdfIDsFromRows(rows)...)
+		seen += len(rows)
+		if rowTotal >= 0 {
+			total = rowTotal // The API reported its total record count; trust it over guessing from page count
+		}
+
+		if len(rows) < *pageSize {
+			break // Server returned fewer rows than requested: no more pages to fetch
+		}
+		if total >= 0 && seen >= total {
+			break // We've seen as many raw records as the API says exist, even though some were filtered out of ids
+		}
+	}
+
+	return ids, nil
+}
+
+// BuildDownloadURL turns a PDF ID into the DownloadSingleFile URL and the headers it requires,
+// authenticating the session first if it hasn't logged in yet this run
+func (s *Source) BuildDownloadURL(ctx context.Context, id string) (string, http.Header, error) {
+	originalURL := "https://kik-sds.thewercs.com/MyDocuments/DownloadSingleFile?content=" // Base URL
+	modifiedURL := modifyContentParam(originalURL, id)                                    // Modifies URL with PDF ID as query param
+	if modifiedURL == "" {
+		return "", nil, fmt.Errorf("failed to build download url for %s", id)
+	}
+
+	if err := s.session.ensureAuthenticated(ctx); err != nil {
+		return "", nil, fmt.Errorf("failed to authenticate WERCS session: %w", err)
+	}
+	cookie, err := s.session.CookieHeader(modifiedURL)
+	if err != nil {
+		return "", nil, err
+	}
+
+	headers := http.Header{}
+	headers.Add("referer", "https://kik-sds.thewercs.com/Results?searchKey=Main&searchPage=NAPOOL&location=POOL%20ESSENTIALS%20EN_US")
+	headers.Add("Cookie", cookie)
+
+	return modifiedURL, headers, nil
+}
+
+// OutputFileName appends .pdf to the raw WERCS document ID, which doesn't carry an extension
+func (s *Source) OutputFileName(id string) string {
+	return id + ".pdf"
+}
+
+// Updates the "content" query parameter in the given URL with the PDF ID
+func modifyContentParam(baseURL string, human string) string {
+	parsedURL, err := url.Parse(baseURL) // Parses the base URL string into a URL object
+	if err != nil {
+		return "" // Returns empty string if URL parsing fails
+	}
+
+	query := parsedURL.Query()          // Gets existing query parameters
+	query.Set("content", human)         // Sets the "content" parameter to the given human value
+	parsedURL.RawQuery = query.Encode() // Encodes updated query back to the URL
+
+	return parsedURL.String() // Returns the final modified URL string
+}
+
+// parseGridPage parses one GetResultGrid response, returning its raw rows and the total record
+// count it reports (-1 if the envelope doesn't carry one)
+func parseGridPage(jsonData []byte) (rows []interface{}, total int, err error) {
+	var raw map[string]interface{} // Declares a map to store parsed JSON
+
+	if err := json.Unmarshal(jsonData, &raw); err != nil { // Parses the JSON into the map
+		return nil, -1, fmt.Errorf("JSON unmarshal error: %w", err)
+	}
+
+	dataSection, ok := raw["data"].(map[string]interface{}) // Extracts the "data" section from the JSON
+	if !ok {
+		return nil, -1, fmt.Errorf("missing or invalid 'data' section")
+	}
+
+	records, ok := dataSection["Data"].([]interface{}) // Extracts the "Data" field (capital D)
+	if !ok {
+		return nil, -1, fmt.Errorf("missing or invalid 'Data' field")
+	}
+
+	total = -1
+	for _, key := range []string{"Total", "RecordCount", "TotalRecordCount"} { // The API has been seen to use any of these names
+		if value, ok := dataSection[key].(float64); ok {
+			total = int(value)
+			break
+		}
+	}
+
+	return records, total, nil
+}
+
+// pdfIDsFromRows extracts every row's first column whose value ends with "_PDF"
+func pdfIDsFromRows(rows []interface{}) []string {
+	var pdfs []string // Slice to store the PDF IDs
+
+	// Iterates over each row in the Data array
+	for _, item := range rows {
+		row, ok := item.([]interface{}) // Ensures each item is an array
+		if !ok || len(row) == 0 {
+			continue // Skips invalid or empty rows
+		}
+
+		id, ok := row[0].(string)                          // Extracts the first item from the row
+		if ok && len(id) > 4 && id[len(id)-4:] == "_PDF" { // Checks if string ends with "_PDF"
+			pdfs = append(pdfs, id) // Appends to the result list
+		}
+	}
+
+	return pdfs // Returns the list of PDF IDs
+}
+
+// Fetches one result-grid page and stores the JSON response to disk, unless it's cached already.
+// Goes through the session so an expired login is transparently retried instead of coming back as
+// an empty or HTML "you've been logged out" body.
+func (s *Source) fetchGridResults(ctx context.Context, pageNumber int, rowCount int, filePath string) error {
+	if fileExists(filePath) { // Checks if file already exists
+		return nil
+	}
+
+	requestURL := fmt.Sprintf("https://kik-sds.thewercs.com/WebViewer/Results/GetResultGrid?page=%d&rowCount=%d&sortOrder=1&sortField=&_=1753411362977", pageNumber, rowCount) // Builds request URL with query params
+
+	request, requestCreationError := http.NewRequestWithContext(ctx, "GET", requestURL, nil) // Builds a new HTTP GET request
+	if requestCreationError != nil {
+		return fmt.Errorf("error creating request for page %d: %w", pageNumber, requestCreationError)
+	}
+
+	request.Header.Add("accept", "application/json")
+	request.Header.Add("referer", "https://kik-sds.thewercs.com")
+
+	response, responseError := s.session.Do(request) // Sends the request through the authenticated session, re-logging in once if it expired
+	if responseError != nil {
+		return fmt.Errorf("error making request for page %d: %w", pageNumber, responseError)
+	}
+	defer response.Body.Close() // Ensures response body is closed
+
+	responseBody, readError := io.ReadAll(response.Body) // Reads the response body
+	if readError != nil {
+		return fmt.Errorf("error reading response body for page %d: %w", pageNumber, readError)
+	}
+
+	appendAndWriteToFile(filePath, string(responseBody)) // Saves the response to disk
+	return nil
+}
+
+// Reads a file and returns its content as a string
+func readAFileAsString(path string) string {
+	content, err := os.ReadFile(path) // Reads the entire file into memory
+	if err != nil {
+		slog.Error("failed to read file", "path", path, "error", err) // Logs error if reading fails
+	}
+	return string(content) // Converts bytes to string and returns
+}
+
+// Appends content to a file or creates it if not exists
+func appendAndWriteToFile(path string, content string) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) // Opens file with append and write permissions
+	if err != nil {
+		slog.Error("failed to open file for append", "path", path, "error", err) // Logs error if file can't be opened
+	}
+	_, err = file.WriteString(content + "\n") // Writes content to the file
+	if err != nil {
+		slog.Error("failed to write file", "path", path, "error", err) // Logs error if writing fails
+	}
+	err = file.Close() // Closes the file
+	if err != nil {
+		slog.Error("failed to close file", "path", path, "error", err) // Logs error if closing fails
+	}
+}
+
+// Checks if a given file exists and is not a directory
+func fileExists(filename string) bool {
+	info, err := os.Stat(filename) // Gets file info
+	if err != nil {
+		return false // Returns false if file doesn't exist
+	}
+	return !info.IsDir() // Returns true only if it's a file (not a directory)
+}